@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/zessam/bank-system/db/dbtest"
+)
+
+// TestCreateDBIsolation demonstrates that dbtest.CreateDB gives each
+// subtest its own database: every subtest creates the same marker table and
+// writes a row keyed by its own id, then asserts it's the only row there.
+// If CreateDB ever handed out a shared database, the CREATE TABLE or the
+// row count would collide between subtests running in parallel.
+func TestCreateDBIsolation(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		i := i
+		t.Run(fmt.Sprintf("subtest-%d", i), func(t *testing.T) {
+			t.Parallel()
+
+			conn := dbtest.CreateDB(t)
+			ctx := context.Background()
+
+			if _, err := conn.Exec(ctx, `CREATE TABLE isolation_marker (id INT PRIMARY KEY)`); err != nil {
+				t.Fatalf("create marker table: %v", err)
+			}
+			if _, err := conn.Exec(ctx, `INSERT INTO isolation_marker (id) VALUES ($1)`, i); err != nil {
+				t.Fatalf("insert marker row: %v", err)
+			}
+
+			var count int
+			if err := conn.QueryRow(ctx, `SELECT count(*) FROM isolation_marker`).Scan(&count); err != nil {
+				t.Fatalf("count marker rows: %v", err)
+			}
+			if count != 1 {
+				t.Fatalf("database is not isolated: expected 1 row, found %d", count)
+			}
+
+			var got int
+			if err := conn.QueryRow(ctx, `SELECT id FROM isolation_marker`).Scan(&got); err != nil {
+				t.Fatalf("read marker row: %v", err)
+			}
+			if got != i {
+				t.Fatalf("database is not isolated: expected row id %d, got %d", i, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolConfig holds the pgxpool tuning knobs requested for this package: this
+// repo slice has no app-level config loader (e.g. a viper-backed util.Config)
+// for NewPool to read from, so PoolConfig itself is the config surface —
+// callers that do have a config loader populate it from there and pass it
+// to NewPool. A zero value leaves pgx's own defaults in place.
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+
+	// Tracer, if set, is attached to every connection the pool opens so
+	// each query is logged and reported as a trace span. Nil disables
+	// tracing.
+	Tracer *QueryTracer
+}
+
+// NewPool parses dsn into a pgxpool.Config, applies any non-zero fields of
+// cfg on top, and opens the pool.
+func NewPool(ctx context.Context, dsn string, cfg PoolConfig) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse pool config: %w", err)
+	}
+
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+	if cfg.Tracer != nil {
+		poolCfg.ConnConfig.Tracer = cfg.Tracer
+	}
+
+	return pgxpool.NewWithConfig(ctx, poolCfg)
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store wraps a *Queries bound to a connection pool and adds transaction
+// support on top of it.
+type Store struct {
+	*Queries
+	connPool *pgxpool.Pool
+}
+
+func NewStore(connPool *pgxpool.Pool) *Store {
+	return &Store{
+		Queries:  New(connPool),
+		connPool: connPool,
+	}
+}
+
+// ExecTx acquires a pool connection, begins a transaction, runs fn with a
+// *Queries bound to it, and commits on success or rolls back on error.
+func (store *Store) ExecTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	if err := fn(q); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer logs every SQL operation run through a traced connection or
+// pool and reports it as a child span of the caller's span, so a query's
+// latency shows up next to the rest of its request's trace.
+type QueryTracer struct {
+	// SlowQueryThreshold is the duration above which a completed query is
+	// logged at WARN with a stack trace instead of DEBUG. Zero disables the
+	// slow-query escalation.
+	SlowQueryThreshold time.Duration
+
+	// Tracer is the OpenTelemetry tracer used to start query spans. Nil
+	// falls back to otel.Tracer("db").
+	Tracer trace.Tracer
+}
+
+type traceCtxKey struct{}
+
+type traceData struct {
+	sql       string
+	args      int
+	startTime time.Time
+	span      trace.Span
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, span := t.tracer().Start(ctx, "db.query")
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.statement", data.SQL),
+	)
+
+	return context.WithValue(ctx, traceCtxKey{}, &traceData{
+		sql:       data.SQL,
+		args:      len(data.Args),
+		startTime: time.Now(),
+		span:      span,
+	})
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	td, ok := ctx.Value(traceCtxKey{}).(*traceData)
+	if !ok {
+		return
+	}
+	defer td.span.End()
+
+	duration := time.Since(td.startTime)
+	rowsAffected := data.CommandTag.RowsAffected()
+	td.span.SetAttributes(attribute.Int64("db.rows_affected", rowsAffected))
+
+	slow := t.SlowQueryThreshold > 0 && duration >= t.SlowQueryThreshold
+
+	var event *zerolog.Event
+	switch {
+	case data.Err != nil:
+		td.span.RecordError(data.Err)
+		td.span.SetStatus(codes.Error, data.Err.Error())
+		event = log.Error().Err(data.Err)
+	case slow:
+		event = log.Warn()
+	default:
+		event = log.Debug()
+	}
+	// Attach the stack independently of which branch chose the level above,
+	// so a query that's both slow and erroring still gets it — it's the
+	// case that most needs it.
+	if slow {
+		event = event.Bytes("stack", debug.Stack())
+	}
+
+	event.
+		Str("sql", td.sql).
+		Int("args", td.args).
+		Dur("duration", duration).
+		Int64("rows_affected", rowsAffected).
+		Msg("db query")
+}
+
+func (t *QueryTracer) tracer() trace.Tracer {
+	if t.Tracer != nil {
+		return t.Tracer
+	}
+	return otel.Tracer("db")
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTestTracer builds a QueryTracer backed by an in-memory span exporter,
+// so tests can assert on spans without a live OpenTelemetry collector.
+func newTestTracer(t *testing.T) (*QueryTracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() {
+		if err := tp.Shutdown(context.Background()); err != nil {
+			t.Logf("shutdown tracer provider: %v", err)
+		}
+	})
+
+	return &QueryTracer{Tracer: tp.Tracer("test")}, exporter
+}
+
+// withCapturedLog points the global zerolog logger at buf for the duration
+// of the test and restores it on cleanup.
+func withCapturedLog(t *testing.T, level zerolog.Level) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buf).Level(level)
+	t.Cleanup(func() { log.Logger = previous })
+	return &buf
+}
+
+func TestQueryTracer_SpanAttributes(t *testing.T) {
+	tracer, exporter := newTestTracer(t)
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL:  "SELECT 1",
+		Args: []interface{}{1, 2},
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{
+		CommandTag: pgconn.NewCommandTag("SELECT 1"),
+	})
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	want := map[string]string{
+		"db.system":    "postgresql",
+		"db.statement": "SELECT 1",
+	}
+	for _, kv := range spans[0].Attributes {
+		if v, ok := want[string(kv.Key)]; ok {
+			if got := kv.Value.AsString(); got != v {
+				t.Errorf("attribute %s = %q, want %q", kv.Key, got, v)
+			}
+			delete(want, string(kv.Key))
+		}
+	}
+	for k := range want {
+		t.Errorf("missing expected attribute %s", k)
+	}
+}
+
+func TestQueryTracer_SlowQueryEscalatesToWarnWithStack(t *testing.T) {
+	tracer, _ := newTestTracer(t)
+	tracer.SlowQueryThreshold = time.Millisecond
+
+	buf := withCapturedLog(t, zerolog.DebugLevel)
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT pg_sleep(1)"})
+	time.Sleep(2 * time.Millisecond)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"level":"warn"`)) {
+		t.Fatalf("expected a warn-level log for a slow query, got: %s", buf.String())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"stack"`)) {
+		t.Fatalf("expected a stack field for a slow query, got: %s", buf.String())
+	}
+}
+
+func TestQueryTracer_FastQueryLogsDebugWithoutStack(t *testing.T) {
+	tracer, _ := newTestTracer(t)
+	tracer.SlowQueryThreshold = time.Hour
+
+	buf := withCapturedLog(t, zerolog.DebugLevel)
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"level":"debug"`)) {
+		t.Fatalf("expected a debug-level log for a fast query, got: %s", buf.String())
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`"stack"`)) {
+		t.Fatalf("did not expect a stack field for a fast query, got: %s", buf.String())
+	}
+}
+
+// TestQueryTracer_SlowErroringQueryKeepsStack guards the bug where
+// reassigning event to the error branch silently dropped the stack field
+// that the slow-query branch had already attached.
+func TestQueryTracer_SlowErroringQueryKeepsStack(t *testing.T) {
+	tracer, _ := newTestTracer(t)
+	tracer.SlowQueryThreshold = time.Millisecond
+
+	buf := withCapturedLog(t, zerolog.DebugLevel)
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	time.Sleep(2 * time.Millisecond)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{
+		CommandTag: pgconn.NewCommandTag("SELECT 1"),
+		Err:        errors.New("boom"),
+	})
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"level":"error"`)) {
+		t.Fatalf("expected an error-level log, got: %s", buf.String())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"stack"`)) {
+		t.Fatalf("expected the stack field to survive on a slow+erroring query, got: %s", buf.String())
+	}
+}